@@ -0,0 +1,169 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package login
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+)
+
+var args struct {
+	tokenURL           string
+	clientID           string
+	clientSecret       string
+	serviceAccountFile string
+	scopes             []string
+	url                string
+	insecure           bool
+	token              string
+}
+
+var Cmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in",
+	Long:  "Log in, saving the credentials to the configuration file.",
+	Args:  cobra.NoArgs,
+	RunE:  run,
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringVar(
+		&args.tokenURL,
+		"token-url",
+		"",
+		"OpenID token URL.",
+	)
+	flags.StringVar(
+		&args.clientID,
+		"client-id",
+		"",
+		"OpenID client identifier.",
+	)
+	flags.StringVar(
+		&args.clientSecret,
+		"client-secret",
+		"",
+		"OpenID client secret.",
+	)
+	flags.StringVar(
+		&args.serviceAccountFile,
+		"service-account-file",
+		"",
+		"Path to a JSON file containing an OpenID client identifier and secret, as an "+
+			"alternative to passing '--client-id' and '--client-secret'. Use this for "+
+			"service accounts, for example in CI pipelines.",
+	)
+	flags.StringSliceVar(
+		&args.scopes,
+		"scope",
+		nil,
+		"OpenID scope. May be used multiple times to request more than one scope.",
+	)
+	flags.StringVar(
+		&args.url,
+		"url",
+		"",
+		"URL of the API gateway.",
+	)
+	flags.BoolVar(
+		&args.insecure,
+		"insecure",
+		false,
+		"Enables insecure communication with the server.",
+	)
+	flags.StringVar(
+		&args.token,
+		"token",
+		"",
+		"Access or refresh token.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) error {
+	if args.serviceAccountFile != "" && (args.clientID != "" || args.clientSecret != "") {
+		return fmt.Errorf(
+			"Options '--service-account-file' and '--client-id'/'--client-secret' are " +
+				"mutually exclusive",
+		)
+	}
+
+	// Load the configuration file, or create an empty one if it doesn't exist yet:
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("Can't load config file: %v", err)
+	}
+	if cfg == nil {
+		cfg = new(config.Config)
+	}
+
+	// Apply the command line options:
+	if args.tokenURL != "" {
+		cfg.TokenURL = args.tokenURL
+	}
+	if args.clientID != "" {
+		cfg.ClientID = args.clientID
+	}
+	if args.clientSecret != "" {
+		cfg.ClientSecret = args.clientSecret
+	}
+	if args.serviceAccountFile != "" {
+		account, err := config.LoadServiceAccount(args.serviceAccountFile)
+		if err != nil {
+			return err
+		}
+		cfg.ClientID = account.ClientID
+		cfg.ClientSecret = account.ClientSecret
+	}
+	if len(args.scopes) > 0 {
+		cfg.Scopes = args.scopes
+	}
+	if args.url != "" {
+		cfg.URL = args.url
+	}
+	if args.insecure {
+		cfg.Insecure = args.insecure
+	}
+	if args.token != "" {
+		cfg.AccessToken = args.token
+		cfg.RefreshToken = ""
+	}
+
+	// Create a connection and use it just to confirm that the credentials are valid:
+	connection, err := cfg.Connection()
+	if err != nil {
+		return fmt.Errorf("Can't create connection: %v", err)
+	}
+	accessToken, refreshToken, err := connection.Tokens()
+	if err != nil {
+		return fmt.Errorf("Can't get token: %v", err)
+	}
+	cfg.AccessToken = accessToken
+	cfg.RefreshToken = refreshToken
+
+	// Save the configuration, which will provision the keyring entry for the sensitive
+	// fields when the OCM_KEYRING environment variable is set:
+	err = config.Save(cfg)
+	if err != nil {
+		return fmt.Errorf("Can't save config file: %v", err)
+	}
+
+	return nil
+}
@@ -18,6 +18,7 @@ package token
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -26,6 +27,15 @@ import (
 
 	"github.com/openshift-online/ocm-cli/pkg/config"
 	"github.com/openshift-online/ocm-cli/pkg/dump"
+	"github.com/openshift-online/ocm-cli/pkg/token/verify"
+)
+
+// Supported values of the '--format' flag.
+const (
+	formatJWT        = "jwt"
+	formatJWKS       = "jwks"
+	formatPEM        = "pem"
+	formatIntrospect = "introspect"
 )
 
 var args struct {
@@ -33,6 +43,8 @@ var args struct {
 	payload   bool
 	signature bool
 	refresh   bool
+	format    string
+	verify    bool
 }
 
 var Cmd = &cobra.Command{
@@ -69,6 +81,22 @@ func init() {
 		false,
 		"Print the refresh token instead of the access token.",
 	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		formatJWT,
+		"Output format, one of 'jwt' (decode locally), 'jwks' (print the signing key "+
+			"from the issuer's JWKS), 'pem' (PEM-encode the signing key) or "+
+			"'introspect' (call the issuer's token introspection endpoint).",
+	)
+	flags.BoolVar(
+		&args.verify,
+		"verify",
+		false,
+		"Verify the token's signature and its 'exp', 'nbf', 'iss' and 'aud' claims "+
+			"against the issuer's JWKS before printing it. Exits with an error if the "+
+			"token doesn't verify.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) error {
@@ -86,6 +114,14 @@ func run(cmd *cobra.Command, argv []string) error {
 	if count > 1 {
 		return fmt.Errorf("Options '--payload', '--header' and '--signature' are mutually exclusive")
 	}
+	switch args.format {
+	case formatJWT, formatJWKS, formatPEM, formatIntrospect:
+	default:
+		return fmt.Errorf(
+			"Format '%s' isn't supported, valid values are '%s', '%s', '%s' and '%s'",
+			args.format, formatJWT, formatJWKS, formatPEM, formatIntrospect,
+		)
+	}
 
 	// Load the configuration file:
 	cfg, err := config.Load()
@@ -125,42 +161,103 @@ func run(cmd *cobra.Command, argv []string) error {
 
 	// Parse the token:
 	parser := new(jwt.Parser)
-	_, parts, err := parser.ParseUnverified(selectedToken, jwt.MapClaims{})
+	claims := jwt.MapClaims{}
+	_, parts, err := parser.ParseUnverified(selectedToken, claims)
 	if err != nil {
 		return fmt.Errorf("Can't parse token: %v", err)
 	}
-	encoding := base64.RawURLEncoding
-	header, err := encoding.DecodeString(parts[0])
-	if err != nil {
-		return fmt.Errorf("Can't decode header: %v", err)
-	}
-	payload, err := encoding.DecodeString(parts[1])
-	if err != nil {
-		return fmt.Errorf("Can't decode payload: %v", err)
-	}
-	signature, err := encoding.DecodeString(parts[2])
-	if err != nil {
-		return fmt.Errorf("Can't decode signature: %v", err)
+
+	// Build an HTTP client that honors the configured '--insecure' setting, for the raw HTTP
+	// calls below that don't go through cfg.Connection():
+	client := cfg.HTTPClient()
+
+	// Verify the token's signature and standard claims, if requested:
+	if args.verify {
+		cacheDir, err := config.Directory()
+		if err != nil {
+			return fmt.Errorf("Can't determine JWKS cache directory: %v", err)
+		}
+		verifier := &verify.Verifier{
+			Audience: cfg.ClientID,
+			Cache:    &verify.Cache{Dir: cacheDir, HTTPClient: client},
+		}
+		_, err = verifier.Token(selectedToken)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Print the data:
-	if args.header {
-		err = dump.Pretty(os.Stdout, header)
+	switch args.format {
+	case formatJWKS:
+		key, err := signingKey(client, claims, parts[0])
+		if err != nil {
+			return err
+		}
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return fmt.Errorf("Can't marshal JWKS key: %v", err)
+		}
+		err = dump.Pretty(os.Stdout, keyJSON)
 		if err != nil {
-			return fmt.Errorf("Can't dump header: %v", err)
+			return fmt.Errorf("Can't dump JWKS key: %v", err)
 		}
-	} else if args.payload {
-		err = dump.Pretty(os.Stdout, payload)
+	case formatPEM:
+		key, err := signingKey(client, claims, parts[0])
 		if err != nil {
-			return fmt.Errorf("Can't dump payload: %v", err)
+			return err
 		}
-	} else if args.signature {
-		err = dump.Pretty(os.Stdout, signature)
+		pem, err := keyToPEM(key)
 		if err != nil {
-			return fmt.Errorf("Can't dump signature: %v", err)
+			return fmt.Errorf("Can't PEM-encode signing key: %v", err)
+		}
+		fmt.Fprintf(os.Stdout, "%s", pem)
+	case formatIntrospect:
+		result, err := introspect(client, cfg, claims, selectedToken)
+		if err != nil {
+			return err
+		}
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("Can't marshal introspection response: %v", err)
+		}
+		err = dump.Pretty(os.Stdout, resultJSON)
+		if err != nil {
+			return fmt.Errorf("Can't dump introspection response: %v", err)
+		}
+	default:
+		encoding := base64.RawURLEncoding
+		header, err := encoding.DecodeString(parts[0])
+		if err != nil {
+			return fmt.Errorf("Can't decode header: %v", err)
+		}
+		payload, err := encoding.DecodeString(parts[1])
+		if err != nil {
+			return fmt.Errorf("Can't decode payload: %v", err)
+		}
+		signature, err := encoding.DecodeString(parts[2])
+		if err != nil {
+			return fmt.Errorf("Can't decode signature: %v", err)
+		}
+
+		// Print the data:
+		if args.header {
+			err = dump.Pretty(os.Stdout, header)
+			if err != nil {
+				return fmt.Errorf("Can't dump header: %v", err)
+			}
+		} else if args.payload {
+			err = dump.Pretty(os.Stdout, payload)
+			if err != nil {
+				return fmt.Errorf("Can't dump payload: %v", err)
+			}
+		} else if args.signature {
+			err = dump.Pretty(os.Stdout, signature)
+			if err != nil {
+				return fmt.Errorf("Can't dump signature: %v", err)
+			}
+		} else {
+			fmt.Fprintf(os.Stdout, "%s\n", selectedToken)
 		}
-	} else {
-		fmt.Fprintf(os.Stdout, "%s\n", selectedToken)
 	}
 
 	// Save the configuration:
@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/openshift-online/ocm-cli/pkg/config"
+	"github.com/openshift-online/ocm-cli/pkg/token/verify"
+)
+
+// discoveryString fetches a string field from the discovery document of the given issuer.
+func discoveryString(client *http.Client, issuer, field string) (value string, err error) {
+	document, err := verify.Discover(client, issuer)
+	if err != nil {
+		return "", err
+	}
+	raw, ok := document[field]
+	if !ok {
+		return "", fmt.Errorf("Discovery document from issuer '%s' doesn't contain a '%s' field", issuer, field)
+	}
+	value, ok = raw.(string)
+	if !ok {
+		return "", fmt.Errorf("Field '%s' of discovery document from issuer '%s' isn't a string", field, issuer)
+	}
+	return value, nil
+}
+
+// issuer extracts the 'iss' claim from the given set of claims.
+func issuer(claims jwt.MapClaims) (value string, err error) {
+	raw, ok := claims["iss"]
+	if !ok {
+		return "", fmt.Errorf("Token doesn't contain an 'iss' claim")
+	}
+	value, ok = raw.(string)
+	if !ok {
+		return "", fmt.Errorf("Claim 'iss' of token isn't a string")
+	}
+	return value, nil
+}
+
+// signingKey resolves the JWKS key that was used to sign the token that produced the given
+// claims and header.
+func signingKey(client *http.Client, claims jwt.MapClaims, header string) (key map[string]interface{}, err error) {
+	iss, err := issuer(claims)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return nil, fmt.Errorf("Can't decode header: %v", err)
+	}
+	parsed := map[string]interface{}{}
+	err = json.Unmarshal(decoded, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse header: %v", err)
+	}
+	kid, _ := parsed["kid"].(string)
+
+	keys, _, err := verify.FetchKeys(client, iss)
+	if err != nil {
+		return nil, err
+	}
+	key, err = selectKey(keys, kid)
+	if err != nil {
+		return nil, fmt.Errorf("%v in JWKS of issuer '%s'", err, iss)
+	}
+	return key, nil
+}
+
+// selectKey returns the JWKS key whose 'kid' field matches the given one exactly, including the
+// case where kid is empty and a key with no 'kid' at all is published. It never guesses, because
+// a guess made during key rotation could pick the wrong signer.
+func selectKey(keys []map[string]interface{}, kid string) (key map[string]interface{}, err error) {
+	for _, candidate := range keys {
+		candidateKid, _ := candidate["kid"].(string)
+		if candidateKid == kid {
+			key = candidate
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("Can't find key with 'kid' '%s'", kid)
+	}
+	return key, nil
+}
+
+// keyToPEM converts an RSA JWKS key to its PEM-encoded public key representation.
+func keyToPEM(key map[string]interface{}) (result []byte, err error) {
+	kty, _ := key["kty"].(string)
+	if kty != "RSA" {
+		return nil, fmt.Errorf("Can't PEM-encode key of type '%s', only 'RSA' is supported", kty)
+	}
+	n, ok := key["n"].(string)
+	if !ok {
+		return nil, fmt.Errorf("JWKS key doesn't contain an 'n' field")
+	}
+	e, ok := key["e"].(string)
+	if !ok {
+		return nil, fmt.Errorf("JWKS key doesn't contain an 'e' field")
+	}
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("Can't decode modulus: %v", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("Can't decode exponent: %v", err)
+	}
+	publicKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("Can't marshal public key: %v", err)
+	}
+	block := &pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: der,
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// introspect calls the issuer's RFC 7662 token introspection endpoint and returns the parsed
+// JSON response.
+func introspect(client *http.Client, cfg *config.Config, claims jwt.MapClaims, token string) (result map[string]interface{}, err error) {
+	iss, err := issuer(claims)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := discoveryString(client, iss, "introspection_endpoint")
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("token", token)
+	request, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("Can't create introspection request: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cfg.ClientID != "" || cfg.ClientSecret != "" {
+		request.SetBasicAuth(cfg.ClientID, cfg.ClientSecret)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("Can't send introspection request to '%s': %v", endpoint, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Introspection request to '%s' failed with status %d", endpoint, response.StatusCode)
+	}
+	result = map[string]interface{}{}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse introspection response from '%s': %v", endpoint, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+// testRSAModulusAndExponent generates an RSA key pair and returns its modulus and exponent
+// base64url-encoded, as they would appear in a JWKS key.
+func testRSAModulusAndExponent(t *testing.T) (n, e string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("can't generate RSA key: %v", err)
+	}
+	n = base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes())
+	return n, e
+}
+
+var keyA = map[string]interface{}{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"}
+var keyB = map[string]interface{}{"kty": "RSA", "kid": "b", "n": "AQAB", "e": "AQAB"}
+var keyNoKid = map[string]interface{}{"kty": "RSA", "n": "AQAB", "e": "AQAB"}
+
+func TestSelectKeyExactMatch(t *testing.T) {
+	keys := []map[string]interface{}{keyA, keyB}
+	key, err := selectKey(keys, "b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key["kid"] != "b" {
+		t.Fatalf("expected key 'b', got '%v'", key["kid"])
+	}
+}
+
+func TestSelectKeyNoKidDoesntGuess(t *testing.T) {
+	// A token with no 'kid' must not silently receive the first published key when the
+	// issuer publishes more than one.
+	keys := []map[string]interface{}{keyA, keyB}
+	_, err := selectKey(keys, "")
+	if err == nil {
+		t.Fatalf("expected an error, got none")
+	}
+}
+
+func TestSelectKeyNoKidMatchesUnambiguousKey(t *testing.T) {
+	keys := []map[string]interface{}{keyNoKid}
+	key, err := selectKey(keys, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key["n"] != "AQAB" {
+		t.Fatalf("unexpected key returned: %v", key)
+	}
+}
+
+func TestSelectKeyUnknownKid(t *testing.T) {
+	keys := []map[string]interface{}{keyA, keyB}
+	_, err := selectKey(keys, "c")
+	if err == nil {
+		t.Fatalf("expected an error for an unknown 'kid', got none")
+	}
+}
+
+func TestKeyToPEM(t *testing.T) {
+	n, e := testRSAModulusAndExponent(t)
+	data, err := keyToPEM(map[string]interface{}{"kty": "RSA", "n": n, "e": e})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		t.Fatalf("expected a PEM encoded public key, got: %s", data)
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("can't parse encoded public key: %v", err)
+	}
+	if _, ok := public.(*rsa.PublicKey); !ok {
+		t.Fatalf("expected an RSA public key, got %T", public)
+	}
+}
+
+func TestKeyToPEMRejectsNonRSA(t *testing.T) {
+	_, err := keyToPEM(map[string]interface{}{"kty": "EC"})
+	if err == nil {
+		t.Fatalf("expected an error for a non RSA key, got none")
+	}
+}
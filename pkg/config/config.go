@@ -0,0 +1,236 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config contains the types and functions used to manage the configuration file used
+// by the command line client.
+package config
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+)
+
+// Config is the type used to store the configuration of the tool.
+type Config struct {
+	AccessToken  string   `json:"access_token,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Insecure     bool     `json:"insecure,omitempty"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	URL          string   `json:"url,omitempty"`
+
+	// Keyring is the name of the OS keyring backend (see the `OCM_KEYRING` environment
+	// variable) that was used to store the sensitive fields of this configuration. When
+	// this is set the `AccessToken`, `RefreshToken` and `ClientSecret` fields above are
+	// empty in the configuration file, and the real values are fetched from, and stored
+	// into, the keyring instead.
+	Keyring string `json:"keyring,omitempty"`
+}
+
+// Load loads the configuration from the configuration file. If the configuration file doesn't
+// exist it returns a nil configuration object and no error.
+func Load() (cfg *Config, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	_, err = os.Stat(file)
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("Can't check if config file '%s' exists: %v", file, err)
+		return
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		err = fmt.Errorf("Can't read config file '%s': %v", file, err)
+		return
+	}
+	cfg = new(Config)
+	if len(data) == 0 {
+		return
+	}
+	err = json.Unmarshal(data, cfg)
+	if err != nil {
+		err = fmt.Errorf("Can't parse config file '%s': %v", file, err)
+		return
+	}
+	err = cfg.load()
+	if err != nil {
+		err = fmt.Errorf("Can't load keyring secrets for config file '%s': %v", file, err)
+		return
+	}
+	return
+}
+
+// Save saves the given configuration to the configuration file.
+func Save(cfg *Config) error {
+	file, err := Location()
+	if err != nil {
+		return err
+	}
+	out := *cfg
+	err = out.store()
+	if err != nil {
+		return fmt.Errorf("Can't store keyring secrets for config file '%s': %v", file, err)
+	}
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Can't marshal config: %v", err)
+	}
+	err = ioutil.WriteFile(file, data, 0600)
+	if err != nil {
+		return fmt.Errorf("Can't write file '%s': %v", file, err)
+	}
+	return nil
+}
+
+// Remove removes the configuration file, along with any secrets that it may have stored in the
+// OS keyring.
+func Remove() error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return nil
+	}
+	err = clearKeyring(cfg.Keyring)
+	if err != nil {
+		return err
+	}
+	file, err := Location()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(file)
+	if err != nil {
+		return fmt.Errorf("Can't remove config file '%s': %v", file, err)
+	}
+	return nil
+}
+
+// Location returns the location of the configuration file.
+func Location() (path string, err error) {
+	if dir := os.Getenv("OCM_CONFIG"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	path = filepath.Join(home, ".ocm.json")
+	return
+}
+
+// Directory returns the directory used to store auxiliary data, such as the cache of JWKS
+// signing keys used by the 'token --verify' command. It is derived by appending a fixed suffix
+// to the configuration file's own path, rather than stripping an extension from it, so it works
+// whether or not OCM_CONFIG points at a file that has one.
+func Directory() (path string, err error) {
+	file, err := Location()
+	if err != nil {
+		return
+	}
+	path = file + ".d"
+	return
+}
+
+// Armed checks if the tokens contained in the configuration are enough to try to log in, and
+// that they haven't expired. A configuration that contains both a client identifier and a
+// client secret is also considered armed, even without tokens.
+func (c *Config) Armed() (armed bool, err error) {
+	if c == nil {
+		return
+	}
+	if c.AccessToken != "" {
+		var expires bool
+		var left time.Duration
+		expires, left, err = tokenExpiry(c.AccessToken)
+		if err != nil {
+			return
+		}
+		if !expires || left > 0 {
+			armed = true
+			return
+		}
+	}
+	if c.RefreshToken != "" {
+		var expires bool
+		var left time.Duration
+		expires, left, err = tokenExpiry(c.RefreshToken)
+		if err != nil {
+			return
+		}
+		if !expires || left > 0 {
+			armed = true
+			return
+		}
+	}
+	if c.ClientID != "" && c.ClientSecret != "" {
+		armed = true
+		return
+	}
+	return
+}
+
+// HTTPClient returns an HTTP client that honors this configuration's Insecure setting. It is
+// used by code that talks to the issuer directly over plain HTTP, such as JWKS discovery and
+// token introspection, instead of going through Connection().
+func (c *Config) HTTPClient() *http.Client {
+	if !c.Insecure {
+		return http.DefaultClient
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+}
+
+// Connection creates a connection using this configuration.
+func (c *Config) Connection() (connection *sdk.Connection, err error) {
+	builder := sdk.NewConnectionBuilder()
+	builder.URL(c.URL)
+	builder.Tokens(c.AccessToken, c.RefreshToken)
+	builder.Insecure(c.Insecure)
+	if c.TokenURL != "" {
+		builder.TokenURL(c.TokenURL)
+	}
+	if c.ClientID != "" || c.ClientSecret != "" {
+		builder.Client(c.ClientID, c.ClientSecret)
+	}
+	if len(c.Scopes) > 0 {
+		builder.Scopes(c.Scopes...)
+	}
+	connection, err = builder.Build()
+	if err != nil {
+		return
+	}
+	return
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectoryDiffersFromConfigFile(t *testing.T) {
+	cases := []string{
+		filepath.Join(t.TempDir(), ".ocm.json"),
+		filepath.Join(t.TempDir(), "config"),
+	}
+	for _, file := range cases {
+		t.Run(file, func(t *testing.T) {
+			t.Setenv("OCM_CONFIG", file)
+			dir, err := Directory()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if dir == file {
+				t.Fatalf("Directory() must not return the same path as the config file itself, got '%s'", dir)
+			}
+			if dir == filepath.Dir(file) {
+				t.Fatalf("Directory() must not return the raw directory containing the config file, got '%s'", dir)
+			}
+		})
+	}
+}
+
+func TestHTTPClientHonorsInsecure(t *testing.T) {
+	secure := &Config{Insecure: false}
+	if secure.HTTPClient() != http.DefaultClient {
+		t.Fatalf("expected the default client when Insecure is false")
+	}
+
+	insecure := &Config{Insecure: true}
+	client := insecure.HTTPClient()
+	if client == http.DefaultClient {
+		t.Fatalf("expected a dedicated client when Insecure is true")
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected an *http.Transport, got %T", client.Transport)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Fatalf("expected InsecureSkipVerify to be set")
+	}
+}
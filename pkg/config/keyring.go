@@ -0,0 +1,216 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/99designs/keyring"
+)
+
+// keyringEnvVar is the name of the environment variable that selects the OS keyring backend
+// used to store the sensitive fields of the configuration. When it isn't set the configuration
+// file stores those fields in plain text, as it always did.
+const keyringEnvVar = "OCM_KEYRING"
+
+// keyringServiceName is the service name used to namespace the entries that this tool stores in
+// the keyring.
+const keyringServiceName = "ocm-cli"
+
+// keyring item keys, one per sensitive field of the configuration.
+const (
+	keyringKeyAccessToken  = "access_token"
+	keyringKeyRefreshToken = "refresh_token"
+	keyringKeyClientSecret = "client_secret"
+)
+
+// backends maps the values accepted by OCM_KEYRING to the corresponding keyring backend type.
+var backends = map[string]keyring.BackendType{
+	"wincred":        keyring.WinCredBackend,
+	"keychain":       keyring.KeychainBackend,
+	"secret-service": keyring.SecretServiceBackend,
+	"pass":           keyring.PassBackend,
+	"file":           keyring.FileBackend,
+}
+
+// openKeyring opens the keyring backend selected by the OCM_KEYRING environment variable. It
+// returns a nil ring and no error when the variable isn't set, meaning that the keyring
+// shouldn't be used.
+func openKeyring() (ring keyring.Keyring, name string, err error) {
+	name = os.Getenv(keyringEnvVar)
+	if name == "" {
+		return
+	}
+	backend, ok := backends[name]
+	if !ok {
+		err = fmt.Errorf(
+			"Keyring backend '%s' isn't supported, valid values are 'wincred', "+
+				"'keychain', 'secret-service', 'pass' and 'file'",
+			name,
+		)
+		return
+	}
+	ring, err = keyring.Open(keyring.Config{
+		ServiceName:     keyringServiceName,
+		AllowedBackends: []keyring.BackendType{backend},
+	})
+	if err != nil {
+		err = fmt.Errorf("Can't open '%s' keyring: %v", name, err)
+		return
+	}
+	return
+}
+
+// load fills in the sensitive fields of the configuration from the keyring, if the configuration
+// indicates that one was used to store them.
+func (c *Config) load() error {
+	if c.Keyring == "" {
+		return nil
+	}
+	ring, name, err := openKeyring()
+	if err != nil {
+		return err
+	}
+	err = checkKeyringName(c.Keyring, name)
+	if err != nil {
+		return err
+	}
+	c.AccessToken, err = getKeyringItem(ring, keyringKeyAccessToken)
+	if err != nil {
+		return err
+	}
+	c.RefreshToken, err = getKeyringItem(ring, keyringKeyRefreshToken)
+	if err != nil {
+		return err
+	}
+	c.ClientSecret, err = getKeyringItem(ring, keyringKeyClientSecret)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkKeyringName verifies that the keyring backend currently selected by the OCM_KEYRING
+// environment variable (selected) is the same one that was used to store the configuration's
+// secrets (configured). It fails loudly instead of letting a silent backend mismatch come back
+// as empty tokens.
+func checkKeyringName(configured, selected string) error {
+	if selected == "" {
+		return fmt.Errorf(
+			"Config was saved with keyring '%s' but the '%s' environment variable "+
+				"isn't set",
+			configured, keyringEnvVar,
+		)
+	}
+	if selected != configured {
+		return fmt.Errorf(
+			"Config was saved with keyring '%s' but the '%s' environment variable "+
+				"selects '%s'",
+			configured, keyringEnvVar, selected,
+		)
+	}
+	return nil
+}
+
+// store moves the sensitive fields of the configuration into the keyring selected by the
+// OCM_KEYRING environment variable, leaving only a reference to the keyring backend in the
+// receiver so that it can be written to the on-disk configuration file.
+func (c *Config) store() error {
+	ring, name, err := openKeyring()
+	if err != nil {
+		return err
+	}
+	if ring == nil {
+		c.Keyring = ""
+		return nil
+	}
+	err = setKeyringItem(ring, keyringKeyAccessToken, c.AccessToken)
+	if err != nil {
+		return err
+	}
+	err = setKeyringItem(ring, keyringKeyRefreshToken, c.RefreshToken)
+	if err != nil {
+		return err
+	}
+	err = setKeyringItem(ring, keyringKeyClientSecret, c.ClientSecret)
+	if err != nil {
+		return err
+	}
+	c.Keyring = name
+	c.AccessToken = ""
+	c.RefreshToken = ""
+	c.ClientSecret = ""
+	return nil
+}
+
+// clearKeyring removes the sensitive fields of the configuration from the keyring backend named
+// by configured, which is the value of the configuration's Keyring field, not the ambient
+// OCM_KEYRING environment variable. It is used by the 'logout' command. If configured is empty
+// the configuration never used a keyring, so there's nothing to clear.
+func clearKeyring(configured string) error {
+	if configured == "" {
+		return nil
+	}
+	ring, selected, err := openKeyring()
+	if err != nil {
+		return err
+	}
+	err = checkKeyringName(configured, selected)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{keyringKeyAccessToken, keyringKeyRefreshToken, keyringKeyClientSecret} {
+		err = ring.Remove(key)
+		if err != nil && err != keyring.ErrKeyNotFound {
+			return fmt.Errorf("Can't remove '%s' from keyring: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func getKeyringItem(ring keyring.Keyring, key string) (value string, err error) {
+	item, err := ring.Get(key)
+	if err != nil {
+		if err == keyring.ErrKeyNotFound {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("Can't read '%s' from keyring: %v", key, err)
+		return
+	}
+	value = string(item.Data)
+	return
+}
+
+func setKeyringItem(ring keyring.Keyring, key, value string) error {
+	if value == "" {
+		err := ring.Remove(key)
+		if err != nil && err != keyring.ErrKeyNotFound {
+			return fmt.Errorf("Can't remove '%s' from keyring: %v", key, err)
+		}
+		return nil
+	}
+	err := ring.Set(keyring.Item{
+		Key:  key,
+		Data: []byte(value),
+	})
+	if err != nil {
+		return fmt.Errorf("Can't write '%s' to keyring: %v", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestKeyringItemRoundTrip(t *testing.T) {
+	ring := keyring.NewArrayKeyring(nil)
+
+	err := setKeyringItem(ring, keyringKeyAccessToken, "my-access-token")
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	value, err := getKeyringItem(ring, keyringKeyAccessToken)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if value != "my-access-token" {
+		t.Fatalf("expected 'my-access-token', got '%s'", value)
+	}
+
+	// Setting an empty value removes the item instead of storing an empty one.
+	err = setKeyringItem(ring, keyringKeyAccessToken, "")
+	if err != nil {
+		t.Fatalf("set empty: %v", err)
+	}
+	value, err = getKeyringItem(ring, keyringKeyAccessToken)
+	if err != nil {
+		t.Fatalf("get after remove: %v", err)
+	}
+	if value != "" {
+		t.Fatalf("expected empty value after removal, got '%s'", value)
+	}
+}
+
+func TestGetKeyringItemMissing(t *testing.T) {
+	ring := keyring.NewArrayKeyring(nil)
+	value, err := getKeyringItem(ring, keyringKeyRefreshToken)
+	if err != nil {
+		t.Fatalf("expected no error for a missing item, got: %v", err)
+	}
+	if value != "" {
+		t.Fatalf("expected empty value, got '%s'", value)
+	}
+}
+
+func TestClearKeyringNoopWhenNeverConfigured(t *testing.T) {
+	// A plaintext config (Keyring == "") has nothing to clear, regardless of what OCM_KEYRING
+	// happens to be set to in the calling shell.
+	t.Setenv(keyringEnvVar, "pass")
+	err := clearKeyring("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClearKeyringFailsLoudlyOnMismatch(t *testing.T) {
+	// Logging out without re-exporting OCM_KEYRING must not silently leave the secrets
+	// behind in the keyring that was actually used to store them.
+	t.Setenv(keyringEnvVar, "")
+	err := clearKeyring("pass")
+	if err == nil {
+		t.Fatalf("expected an error when OCM_KEYRING isn't set, got none")
+	}
+}
+
+func TestCheckKeyringName(t *testing.T) {
+	cases := []struct {
+		name       string
+		configured string
+		selected   string
+		wantErr    bool
+	}{
+		{"matches", "pass", "pass", false},
+		{"not set", "pass", "", true},
+		{"mismatch", "pass", "keychain", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkKeyringName(c.configured, c.selected)
+			if c.wantErr && err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
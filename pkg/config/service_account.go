@@ -0,0 +1,53 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// ServiceAccount contains the OAuth2 client credentials used to authenticate as a service
+// account instead of as an interactive user.
+type ServiceAccount struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// LoadServiceAccount reads a service account file and returns the client credentials that it
+// contains. This is intended for automation use cases, such as CI pipelines, where an
+// interactive login and an offline refresh token aren't available.
+func LoadServiceAccount(file string) (account *ServiceAccount, err error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read service account file '%s': %v", file, err)
+	}
+	account = new(ServiceAccount)
+	err = json.Unmarshal(data, account)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse service account file '%s': %v", file, err)
+	}
+	if account.ClientID == "" || account.ClientSecret == "" {
+		return nil, fmt.Errorf(
+			"Service account file '%s' must contain non empty 'client_id' and "+
+				"'client_secret' fields",
+			file,
+		)
+	}
+	return account, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func writeServiceAccountFile(t *testing.T, contents string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "service-account.json")
+	err := ioutil.WriteFile(file, []byte(contents), 0600)
+	if err != nil {
+		t.Fatalf("can't write service account file: %v", err)
+	}
+	return file
+}
+
+func TestLoadServiceAccount(t *testing.T) {
+	file := writeServiceAccountFile(t, `{"client_id": "my-id", "client_secret": "my-secret"}`)
+	account, err := LoadServiceAccount(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if account.ClientID != "my-id" || account.ClientSecret != "my-secret" {
+		t.Fatalf("unexpected account: %+v", account)
+	}
+}
+
+func TestLoadServiceAccountMissingFields(t *testing.T) {
+	cases := []string{
+		`{}`,
+		`{"client_id": "my-id"}`,
+		`{"client_secret": "my-secret"}`,
+	}
+	for _, contents := range cases {
+		file := writeServiceAccountFile(t, contents)
+		_, err := LoadServiceAccount(file)
+		if err == nil {
+			t.Fatalf("expected an error for contents %q, got none", contents)
+		}
+	}
+}
+
+func TestLoadServiceAccountInvalidJSON(t *testing.T) {
+	file := writeServiceAccountFile(t, `not json`)
+	_, err := LoadServiceAccount(file)
+	if err == nil {
+		t.Fatalf("expected an error for invalid JSON, got none")
+	}
+}
+
+func TestLoadServiceAccountMissingFile(t *testing.T) {
+	_, err := LoadServiceAccount(filepath.Join(t.TempDir(), "doesnt-exist.json"))
+	if err == nil {
+		t.Fatalf("expected an error for a missing file, got none")
+	}
+}
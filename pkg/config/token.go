@@ -0,0 +1,50 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// tokenExpiry parses the given token and returns whether it has an expiry claim and, if so, how
+// much time is left until it expires. Tokens that can't be parsed as JWT tokens (for example
+// opaque refresh tokens issued by some identity providers) are reported as not expiring.
+func tokenExpiry(token string) (expires bool, left time.Duration, err error) {
+	parser := new(jwt.Parser)
+	claims := jwt.MapClaims{}
+	_, _, err = parser.ParseUnverified(token, claims)
+	if err != nil {
+		expires = false
+		err = nil
+		return
+	}
+	claim, ok := claims["exp"]
+	if !ok {
+		return
+	}
+	when, ok := claim.(float64)
+	if !ok {
+		err = fmt.Errorf("Expiration time claim 'exp' has unexpected type '%T'", claim)
+		return
+	}
+	expires = true
+	left = time.Until(time.Unix(int64(when), 0))
+	return
+}
@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dump contains helpers used to print JSON data in a human friendly way.
+package dump
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Pretty writes to the given writer a human friendly representation of the given JSON
+// document.
+func Pretty(writer io.Writer, data []byte) error {
+	buffer := new(bytes.Buffer)
+	err := json.Indent(buffer, data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Can't indent JSON document: %v", err)
+	}
+	_, err = writer.Write(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer)
+	return err
+}
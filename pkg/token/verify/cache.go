@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is the time that a cached JWKS key is considered fresh when the issuer's response
+// doesn't carry a 'Cache-Control' header.
+const DefaultTTL = 24 * time.Hour
+
+// Cache is a disk backed cache of JWKS signing keys, keyed by issuer and 'kid'. Entries older
+// than their TTL are refreshed automatically, and a lookup for a 'kid' that isn't in the cache
+// always triggers a forced refresh before giving up.
+type Cache struct {
+	// Dir is the directory where cached keys are stored. It is created on first use.
+	Dir string
+
+	// TTL is the time that a cached key is considered fresh when the issuer doesn't specify
+	// a 'max-age' via the 'Cache-Control' response header. Defaults to DefaultTTL.
+	TTL time.Duration
+
+	// HTTPClient is used to fetch the issuer's discovery document and JWKS. Defaults to
+	// http.DefaultClient. Set it to a client with a custom TLS configuration to talk to
+	// issuers with self-signed certificates.
+	HTTPClient *http.Client
+}
+
+// entry is the on-disk representation of a cached key.
+type entry struct {
+	FetchedAt int64           `json:"fetched_at"`
+	MaxAge    int64           `json:"max_age"`
+	Key       json.RawMessage `json:"key"`
+}
+
+// Get returns the JWKS key identified by the given issuer and 'kid', fetching it from the
+// issuer's JWKS endpoint and populating the cache if needed.
+func (c *Cache) Get(issuer, kid string) (key map[string]interface{}, err error) {
+	ttl := c.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	path := c.path(issuer, kid)
+	if cached, ok := c.read(path); ok {
+		age := time.Since(time.Unix(cached.FetchedAt, 0))
+		maxAge := ttl
+		if cached.MaxAge > 0 {
+			maxAge = time.Duration(cached.MaxAge) * time.Second
+		}
+		if age < maxAge {
+			key = map[string]interface{}{}
+			err = json.Unmarshal(cached.Key, &key)
+			if err != nil {
+				return nil, fmt.Errorf("Can't parse cached key: %v", err)
+			}
+			return key, nil
+		}
+	}
+
+	// Cache miss, or the cached entry has expired: force a refresh from the issuer.
+	keys, maxAge, err := FetchKeys(c.httpClient(), issuer)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range keys {
+		candidateKid, _ := candidate["kid"].(string)
+		if candidateKid != kid {
+			continue
+		}
+		key = candidate
+		break
+	}
+	if key == nil {
+		return nil, fmt.Errorf("Can't find key with 'kid' '%s' in JWKS of issuer '%s'", kid, issuer)
+	}
+	keyData, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("Can't marshal key: %v", err)
+	}
+	c.write(path, &entry{
+		FetchedAt: time.Now().Unix(),
+		MaxAge:    int64(maxAge.Seconds()),
+		Key:       keyData,
+	})
+	return key, nil
+}
+
+func (c *Cache) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Cache) path(issuer, kid string) string {
+	hash := sha256.Sum256([]byte(issuer + "#" + kid))
+	return filepath.Join(c.Dir, "jwks-cache", hex.EncodeToString(hash[:])+".json")
+}
+
+func (c *Cache) read(path string) (result *entry, ok bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	result = new(entry)
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+func (c *Cache) write(path string, e *entry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(path, data, 0600)
+}
+
+// DiscoveryPath is the well known path, relative to the issuer, of the OIDC discovery document.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// Discover fetches and parses the OIDC discovery document published by the given issuer, using
+// the given HTTP client. It is exported so that other packages that need the discovery document,
+// such as the 'token' command's '--format=introspect' support, don't have to reimplement the
+// fetch.
+func Discover(client *http.Client, issuer string) (document map[string]interface{}, err error) {
+	response, err := client.Get(strings.TrimRight(issuer, "/") + DiscoveryPath)
+	if err != nil {
+		return nil, fmt.Errorf("Can't fetch discovery document from issuer '%s': %v", issuer, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"Discovery document request to issuer '%s' failed with status %d",
+			issuer, response.StatusCode,
+		)
+	}
+	document = map[string]interface{}{}
+	err = json.NewDecoder(response.Body).Decode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("Can't parse discovery document from issuer '%s': %v", issuer, err)
+	}
+	return document, nil
+}
+
+// FetchKeys fetches the full set of JWKS keys published by the given issuer, using the given
+// HTTP client and resolving the JWKS endpoint from the issuer's OIDC discovery document, along
+// with the 'max-age' directive of the 'Cache-Control' header of the response, if any. It is
+// exported so that the 'token' command's '--format=jwks' and '--format=pem' support can resolve
+// a signing key without duplicating this fetch.
+func FetchKeys(client *http.Client, issuer string) (keys []map[string]interface{}, maxAge time.Duration, err error) {
+	document, err := Discover(client, issuer)
+	if err != nil {
+		return nil, 0, err
+	}
+	jwksURI, ok := document["jwks_uri"].(string)
+	if !ok || jwksURI == "" {
+		return nil, 0, fmt.Errorf("Discovery document from issuer '%s' doesn't contain a 'jwks_uri'", issuer)
+	}
+
+	jwksResponse, err := client.Get(jwksURI)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Can't fetch JWKS from '%s': %v", jwksURI, err)
+	}
+	defer jwksResponse.Body.Close()
+	if jwksResponse.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf(
+			"JWKS request to '%s' failed with status %d", jwksURI, jwksResponse.StatusCode,
+		)
+	}
+	var jwks struct {
+		Keys []map[string]interface{} `json:"keys"`
+	}
+	err = json.NewDecoder(jwksResponse.Body).Decode(&jwks)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Can't parse JWKS from '%s': %v", jwksURI, err)
+	}
+	maxAge = maxAgeOf(jwksResponse.Header.Get("Cache-Control"))
+	return jwks.Keys, maxAge, nil
+}
+
+// maxAgeOf extracts the 'max-age' directive from a 'Cache-Control' header value. It returns zero
+// when the header is absent or doesn't contain a valid 'max-age' directive, in which case the
+// cache's default TTL should be used instead.
+func maxAgeOf(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age") {
+			continue
+		}
+		parts := strings.SplitN(directive, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || seconds <= 0 {
+			continue
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMaxAgeOf(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"absent", "", 0},
+		{"simple", "max-age=3600", 3600 * time.Second},
+		{"with other directives", "public, max-age=60, immutable", 60 * time.Second},
+		{"zero is ignored", "max-age=0", 0},
+		{"negative is ignored", "max-age=-1", 0},
+		{"garbage is ignored", "max-age=soon", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := maxAgeOf(c.header)
+			if got != c.want {
+				t.Fatalf("maxAgeOf(%q) = %v, want %v", c.header, got, c.want)
+			}
+		})
+	}
+}
+
+// newTestIssuer starts an OIDC-ish server exposing a discovery document and a JWKS with whatever
+// keys *keys points at, letting the caller mutate the published set between requests to simulate
+// key rotation. It returns the test server, whose URL should be used as the 'iss' claim.
+func newTestIssuer(t *testing.T, keys *[]map[string]interface{}, cacheControl string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jwks_uri": "http://" + r.Host + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": *keys,
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCacheGetFetchesAndCaches(t *testing.T) {
+	keys := []map[string]interface{}{
+		{"kty": "RSA", "kid": "k1", "n": "AQAB", "e": "AQAB"},
+	}
+	server := newTestIssuer(t, &keys, "max-age=3600")
+
+	cache := &Cache{Dir: t.TempDir()}
+	key, err := cache.Get(server.URL, "k1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key["kid"] != "k1" {
+		t.Fatalf("unexpected key: %v", key)
+	}
+
+	// A second lookup for the same 'kid' should be served from the cache, not the server,
+	// confirmed by the entry actually existing on disk.
+	path := cache.path(server.URL, "k1")
+	if _, ok := cache.read(path); !ok {
+		t.Fatalf("expected the key to have been cached to disk at %s", path)
+	}
+}
+
+func TestCacheGetMissingKidFails(t *testing.T) {
+	keys := []map[string]interface{}{
+		{"kty": "RSA", "kid": "k1", "n": "AQAB", "e": "AQAB"},
+	}
+	server := newTestIssuer(t, &keys, "")
+	cache := &Cache{Dir: t.TempDir()}
+	_, err := cache.Get(server.URL, "does-not-exist")
+	if err == nil {
+		t.Fatalf("expected an error for an unknown 'kid', got none")
+	}
+}
+
+func TestCacheGetForcesRefreshOnKidMiss(t *testing.T) {
+	keys := []map[string]interface{}{
+		{"kty": "RSA", "kid": "k1", "n": "AQAB", "e": "AQAB"},
+	}
+	server := newTestIssuer(t, &keys, "max-age=3600")
+	cache := &Cache{Dir: t.TempDir()}
+
+	_, err := cache.Get(server.URL, "k1")
+	if err != nil {
+		t.Fatalf("unexpected error priming the cache: %v", err)
+	}
+
+	// Simulate key rotation: the issuer now also publishes 'k2'. A lookup for 'k2' isn't
+	// satisfied by the cache, so it must force a refresh instead of failing outright.
+	keys = append(keys, map[string]interface{}{"kty": "RSA", "kid": "k2", "n": "AQAB", "e": "AQAB"})
+	key, err := cache.Get(server.URL, "k2")
+	if err != nil {
+		t.Fatalf("expected the cache to refresh and find 'k2', got: %v", err)
+	}
+	if key["kid"] != "k2" {
+		t.Fatalf("unexpected key: %v", key)
+	}
+}
+
+func TestFetchKeysRejectsMissingJWKSURI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	_, _, err := FetchKeys(http.DefaultClient, server.URL)
+	if err == nil {
+		t.Fatalf("expected an error, got none")
+	}
+}
+
+func TestFetchKeysUsesGivenClientForTLS(t *testing.T) {
+	keys := []map[string]interface{}{
+		{"kty": "RSA", "kid": "k1", "n": "AQAB", "e": "AQAB"},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"jwks_uri": "https://" + r.Host + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": keys,
+		})
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	// The default client doesn't trust the test server's self-signed certificate:
+	_, _, err := FetchKeys(http.DefaultClient, server.URL)
+	if err == nil {
+		t.Fatalf("expected the default client to reject the self-signed certificate")
+	}
+
+	// A client that trusts the test server's certificate succeeds, so the given client is
+	// the one used for both the discovery and the JWKS requests:
+	insecure := server.Client()
+	_, _, err = FetchKeys(insecure, server.URL)
+	if err != nil {
+		t.Fatalf("expected the insecure client to succeed, got: %v", err)
+	}
+}
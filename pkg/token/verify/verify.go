@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package verify validates the signature and standard claims of JWT tokens issued by the SSO
+// server, using keys fetched from the issuer's JWKS endpoint and cached on disk.
+package verify
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Verifier verifies JWT tokens against the JWKS published by their issuer.
+type Verifier struct {
+	// Audience, when set, is checked against the token's 'aud' claim.
+	Audience string
+
+	// Cache is the JWKS key cache used to resolve signing keys. It is required.
+	Cache *Cache
+}
+
+// Token verifies the signature of the given token, as well as its 'exp', 'nbf', 'iss' and 'aud'
+// claims, and returns the parsed token on success. The 'iss' claim must be present and is used
+// to locate the issuer's JWKS; it isn't compared against an expected issuer.
+func (v *Verifier) Token(tokenString string) (token *jwt.Token, err error) {
+	token, err = jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		claims, ok := t.Claims.(jwt.MapClaims)
+		if !ok {
+			return nil, fmt.Errorf("Token claims have unexpected type '%T'", t.Claims)
+		}
+		iss, ok := claims["iss"].(string)
+		if !ok || iss == "" {
+			return nil, fmt.Errorf("Token doesn't contain an 'iss' claim")
+		}
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.Cache.Get(iss, kid)
+		if err != nil {
+			return nil, err
+		}
+		return keyToPublicKey(key)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Can't verify token: %v", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("Token claims have unexpected type '%T'", token.Claims)
+	}
+	if _, ok := claims["iss"]; !ok {
+		return nil, fmt.Errorf("Token doesn't contain an 'iss' claim")
+	}
+	if v.Audience != "" && !claims.VerifyAudience(v.Audience, true) {
+		return nil, fmt.Errorf("Token audience doesn't match expected audience '%s'", v.Audience)
+	}
+	return token, nil
+}
+
+// keyToPublicKey converts an RSA JWKS key to a *rsa.PublicKey that the 'jwt-go' package can use
+// to verify a signature.
+func keyToPublicKey(key map[string]interface{}) (*rsa.PublicKey, error) {
+	kty, _ := key["kty"].(string)
+	if kty != "RSA" {
+		return nil, fmt.Errorf("Can't use key of type '%s' to verify signature, only 'RSA' is supported", kty)
+	}
+	n, ok := key["n"].(string)
+	if !ok {
+		return nil, fmt.Errorf("JWKS key doesn't contain an 'n' field")
+	}
+	e, ok := key["e"].(string)
+	if !ok {
+		return nil, fmt.Errorf("JWKS key doesn't contain an 'e' field")
+	}
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("Can't decode modulus: %v", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("Can't decode exponent: %v", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (c) 2018 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verify
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// newTestTokenIssuer generates an RSA key pair, starts a JWKS server that publishes its public
+// half under the given 'kid', and returns the issuer URL together with a signer for that key.
+func newTestTokenIssuer(t *testing.T, kid string) (issuer string, private *rsa.PrivateKey) {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("can't generate RSA key: %v", err)
+	}
+	n := base64.RawURLEncoding.EncodeToString(private.PublicKey.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(private.PublicKey.E)).Bytes())
+	keys := []map[string]interface{}{
+		{"kty": "RSA", "kid": kid, "n": n, "e": e},
+	}
+	server := newTestIssuer(t, &keys, "")
+	return server.URL, private
+}
+
+func signTestToken(t *testing.T, private *rsa.PrivateKey, kid, issuer, audience string, expiresIn time.Duration) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"aud": audience,
+		"exp": time.Now().Add(expiresIn).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(private)
+	if err != nil {
+		t.Fatalf("can't sign test token: %v", err)
+	}
+	return signed
+}
+
+func TestVerifierAcceptsGenuineToken(t *testing.T) {
+	issuer, private := newTestTokenIssuer(t, "k1")
+	tokenString := signTestToken(t, private, "k1", issuer, "my-client", time.Hour)
+
+	verifier := &Verifier{Audience: "my-client", Cache: &Cache{Dir: t.TempDir()}}
+	_, err := verifier.Token(tokenString)
+	if err != nil {
+		t.Fatalf("unexpected error verifying a genuine token: %v", err)
+	}
+}
+
+func TestVerifierRejectsTamperedSignature(t *testing.T) {
+	issuer, private := newTestTokenIssuer(t, "k1")
+	tokenString := signTestToken(t, private, "k1", issuer, "my-client", time.Hour)
+
+	// Flip a character in the signature segment to simulate a locally tampered token. The
+	// first character of the segment is used because all of its bits are significant.
+	dot := strings.LastIndex(tokenString, ".")
+	replacement := "A"
+	if tokenString[dot+1] == 'A' {
+		replacement = "B"
+	}
+	tampered := tokenString[:dot+1] + replacement + tokenString[dot+2:]
+
+	verifier := &Verifier{Audience: "my-client", Cache: &Cache{Dir: t.TempDir()}}
+	_, err := verifier.Token(tampered)
+	if err == nil {
+		t.Fatalf("expected a tampered signature to be rejected, but it verified")
+	}
+}
+
+func TestVerifierRejectsExpiredToken(t *testing.T) {
+	issuer, private := newTestTokenIssuer(t, "k1")
+	tokenString := signTestToken(t, private, "k1", issuer, "my-client", -time.Hour)
+
+	verifier := &Verifier{Audience: "my-client", Cache: &Cache{Dir: t.TempDir()}}
+	_, err := verifier.Token(tokenString)
+	if err == nil {
+		t.Fatalf("expected an expired token to be rejected, but it verified")
+	}
+}
+
+func TestVerifierRejectsWrongAudience(t *testing.T) {
+	issuer, private := newTestTokenIssuer(t, "k1")
+	tokenString := signTestToken(t, private, "k1", issuer, "someone-else", time.Hour)
+
+	verifier := &Verifier{Audience: "my-client", Cache: &Cache{Dir: t.TempDir()}}
+	_, err := verifier.Token(tokenString)
+	if err == nil {
+		t.Fatalf("expected a token with the wrong audience to be rejected, but it verified")
+	}
+}